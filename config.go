@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// duration wraps time.Duration so it can be decoded from a TOML string
+// such as "10s" (BurntSushi/toml only decodes durations that implement
+// encoding.TextUnmarshaler).
+type duration time.Duration
+
+// UnmarshalText parses a duration string like "10s" or "1m30s".
+func (d *duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+// String renders a duration the same way time.Duration does, e.g. "10s".
+func (d duration) String() string {
+	return time.Duration(d).String()
+}
+
+// ServerConfig controls the HTTP server's listen address and timeouts.
+type ServerConfig struct {
+	ListenAddr    string   `toml:"listen_addr"`
+	ReadTimeout   duration `toml:"read_timeout"`
+	WriteTimeout  duration `toml:"write_timeout"`
+	IdleTimeout   duration `toml:"idle_timeout"`
+	ShutdownGrace duration `toml:"shutdown_grace"`
+}
+
+// LoggingConfig controls how the service logs.
+type LoggingConfig struct {
+	Level  string `toml:"level"`
+	Format string `toml:"format"`
+}
+
+// Config is the top-level application configuration, loaded from a TOML
+// file and overridable via environment variables.
+type Config struct {
+	Server  ServerConfig  `toml:"server"`
+	Logging LoggingConfig `toml:"logging"`
+}
+
+// defaultConfig returns the configuration used when no file is supplied
+// and no overriding environment variables are set.
+func defaultConfig() Config {
+	return Config{
+		Server: ServerConfig{
+			ListenAddr:    ":8080",
+			ReadTimeout:   duration(10 * time.Second),
+			WriteTimeout:  duration(10 * time.Second),
+			IdleTimeout:   duration(60 * time.Second),
+			ShutdownGrace: duration(10 * time.Second),
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+}
+
+// LoadConfig reads the TOML config file at path (if path is non-empty),
+// layers RECEIPT_SERVER_* / RECEIPT_LOGGING_* environment variable
+// overrides on top, validates the result, and returns it.
+func LoadConfig(path string) (*Config, error) {
+	cfg := defaultConfig()
+
+	if path != "" {
+		if _, err := toml.DecodeFile(path, &cfg); err != nil {
+			return nil, fmt.Errorf("reading config file %s: %w", path, err)
+		}
+	}
+
+	applyConfigEnvOverrides(&cfg)
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// applyConfigEnvOverrides layers environment variable overrides onto cfg.
+// Environment variables take precedence over the config file so the same
+// binary and config file can be reused across environments.
+func applyConfigEnvOverrides(cfg *Config) {
+	if v := os.Getenv("RECEIPT_SERVER_LISTEN_ADDR"); v != "" {
+		cfg.Server.ListenAddr = v
+	}
+	if v, ok := parseDurationEnv("RECEIPT_SERVER_READ_TIMEOUT"); ok {
+		cfg.Server.ReadTimeout = duration(v)
+	}
+	if v, ok := parseDurationEnv("RECEIPT_SERVER_WRITE_TIMEOUT"); ok {
+		cfg.Server.WriteTimeout = duration(v)
+	}
+	if v, ok := parseDurationEnv("RECEIPT_SERVER_IDLE_TIMEOUT"); ok {
+		cfg.Server.IdleTimeout = duration(v)
+	}
+	if v, ok := parseDurationEnv("RECEIPT_SERVER_SHUTDOWN_GRACE"); ok {
+		cfg.Server.ShutdownGrace = duration(v)
+	}
+	if v := os.Getenv("RECEIPT_LOGGING_LEVEL"); v != "" {
+		cfg.Logging.Level = v
+	}
+	if v := os.Getenv("RECEIPT_LOGGING_FORMAT"); v != "" {
+		cfg.Logging.Format = v
+	}
+}
+
+// parseDurationEnv reads and parses an environment variable as a
+// time.Duration. The second return value is false if the variable is
+// unset or malformed (malformed values are ignored rather than treated
+// as fatal, since validate() will catch anything that matters).
+func parseDurationEnv(name string) (time.Duration, bool) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// validate checks that a Config is safe to build a server from.
+func (c *Config) validate() error {
+	if c.Server.ListenAddr == "" {
+		return fmt.Errorf("server.listen_addr must not be empty")
+	}
+	if c.Server.ReadTimeout <= 0 {
+		return fmt.Errorf("server.read_timeout must be positive, got %s", c.Server.ReadTimeout)
+	}
+	if c.Server.WriteTimeout <= 0 {
+		return fmt.Errorf("server.write_timeout must be positive, got %s", c.Server.WriteTimeout)
+	}
+	if c.Server.IdleTimeout <= 0 {
+		return fmt.Errorf("server.idle_timeout must be positive, got %s", c.Server.IdleTimeout)
+	}
+	if c.Server.ShutdownGrace <= 0 {
+		return fmt.Errorf("server.shutdown_grace must be positive, got %s", c.Server.ShutdownGrace)
+	}
+	switch c.Logging.Format {
+	case "text", "json":
+	default:
+		return fmt.Errorf("logging.format must be \"text\" or \"json\", got %q", c.Logging.Format)
+	}
+	return nil
+}
+
+// ServerTimeouts returns the server's configured timeouts as
+// time.Duration values, ready to assign onto an http.Server.
+func (c *Config) ServerTimeouts() (read, write, idle, shutdownGrace time.Duration) {
+	return time.Duration(c.Server.ReadTimeout),
+		time.Duration(c.Server.WriteTimeout),
+		time.Duration(c.Server.IdleTimeout),
+		time.Duration(c.Server.ShutdownGrace)
+}
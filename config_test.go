@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTempConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write temp config: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfig_Defaults(t *testing.T) {
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig(\"\") returned error: %v", err)
+	}
+	if cfg.Server.ListenAddr != ":8080" {
+		t.Errorf("ListenAddr = %q, want %q", cfg.Server.ListenAddr, ":8080")
+	}
+	if time.Duration(cfg.Server.ReadTimeout) != 10*time.Second {
+		t.Errorf("ReadTimeout = %s, want 10s", cfg.Server.ReadTimeout)
+	}
+}
+
+func TestLoadConfig_ValidFile(t *testing.T) {
+	path := writeTempConfig(t, `
+[server]
+listen_addr = ":9090"
+read_timeout = "5s"
+write_timeout = "5s"
+idle_timeout = "30s"
+shutdown_grace = "3s"
+
+[logging]
+level = "debug"
+format = "json"
+`)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() returned error: %v", err)
+	}
+	if cfg.Server.ListenAddr != ":9090" {
+		t.Errorf("ListenAddr = %q, want %q", cfg.Server.ListenAddr, ":9090")
+	}
+	if time.Duration(cfg.Server.ReadTimeout) != 5*time.Second {
+		t.Errorf("ReadTimeout = %s, want 5s", cfg.Server.ReadTimeout)
+	}
+	if cfg.Logging.Format != "json" {
+		t.Errorf("Logging.Format = %q, want %q", cfg.Logging.Format, "json")
+	}
+}
+
+func TestLoadConfig_RejectsBadConfigs(t *testing.T) {
+	tests := map[string]string{
+		"zero read timeout": `
+[server]
+listen_addr = ":8080"
+read_timeout = "0s"
+write_timeout = "10s"
+idle_timeout = "60s"
+shutdown_grace = "10s"
+`,
+		"empty listen addr": `
+[server]
+listen_addr = ""
+read_timeout = "10s"
+write_timeout = "10s"
+idle_timeout = "60s"
+shutdown_grace = "10s"
+`,
+		"invalid logging format": `
+[server]
+listen_addr = ":8080"
+read_timeout = "10s"
+write_timeout = "10s"
+idle_timeout = "60s"
+shutdown_grace = "10s"
+
+[logging]
+format = "xml"
+`,
+	}
+
+	for name, contents := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := writeTempConfig(t, contents)
+			if _, err := LoadConfig(path); err == nil {
+				t.Fatal("expected LoadConfig() to reject invalid config, got nil error")
+			}
+		})
+	}
+}
+
+func TestLoadConfig_EnvOverride(t *testing.T) {
+	t.Setenv("RECEIPT_SERVER_LISTEN_ADDR", ":9999")
+
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() returned error: %v", err)
+	}
+	if cfg.Server.ListenAddr != ":9999" {
+		t.Errorf("ListenAddr = %q, want %q (from env override)", cfg.Server.ListenAddr, ":9999")
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// receiptHandler holds the dependencies the receipt endpoints need. Using
+// a struct instead of package-level state lets the storage backend and
+// rule set be swapped (e.g. in tests) without touching global variables.
+type receiptHandler struct {
+	repo   ReceiptRepository
+	rules  *RuleSet
+	ledger *PointsLedger
+}
+
+// newReceiptHandler returns a receiptHandler backed by repo, awarding
+// points according to rules.
+func newReceiptHandler(repo ReceiptRepository, rules *RuleSet) *receiptHandler {
+	return &receiptHandler{repo: repo, rules: rules, ledger: NewPointsLedger(repo)}
+}
+
+// newReceiptRepositoryFromEnv selects a ReceiptRepository implementation
+// based on the RECEIPT_STORE environment variable ("memory", "sqlite", or
+// "postgres"; defaults to "memory"). The sqlite and postgres backends
+// additionally read their connection string from RECEIPT_STORE_DSN.
+func newReceiptRepositoryFromEnv() (ReceiptRepository, error) {
+	switch backend := os.Getenv("RECEIPT_STORE"); backend {
+	case "", "memory":
+		return newMemoryReceiptRepository(), nil
+	case "sqlite":
+		dsn := os.Getenv("RECEIPT_STORE_DSN")
+		if dsn == "" {
+			dsn = "receipts.db"
+		}
+		return newSQLiteReceiptRepository(dsn)
+	case "postgres":
+		dsn := os.Getenv("RECEIPT_STORE_DSN")
+		if dsn == "" {
+			return nil, fmt.Errorf("RECEIPT_STORE_DSN must be set when RECEIPT_STORE=postgres")
+		}
+		return newPostgresReceiptRepository(dsn)
+	default:
+		return nil, fmt.Errorf("unknown RECEIPT_STORE backend %q", backend)
+	}
+}
+
+// processReceipt handles the submission of a receipt.
+// It parses the JSON request body and, unless an identical receipt has
+// already been processed, generates a unique receipt ID, saves the
+// receipt and its calculated points through the repository, and returns
+// the ID.
+//
+// The request is treated as idempotent: if it carries an Idempotency-Key
+// header, that key is used to detect retries; otherwise a canonical hash
+// of the receipt contents is used. Either way, a retry of a previously
+// seen receipt returns the original ID instead of minting a new one.
+func (h *receiptHandler) processReceipt(context *gin.Context) {
+	var receipt Receipt
+
+	// If the JSON is invalid, return a 400 Bad Request response.
+	if err := context.ShouldBindJSON(&receipt); err != nil {
+		log.Println("Failed to bind receipt JSON:", err)
+		context.JSON(http.StatusBadRequest, gin.H{"error": "The receipt is invalid."})
+		return
+	}
+
+	contentHash := canonicalReceiptHash(receipt)
+	idempotencyKey := context.GetHeader("Idempotency-Key")
+
+	storeLock.Lock()
+	defer storeLock.Unlock()
+
+	if idempotencyKey != "" {
+		if record, exists := idempotencyKeys[idempotencyKey]; exists {
+			if record.Hash != contentHash {
+				log.Println("Idempotency-Key reused with a different receipt:", idempotencyKey)
+				context.JSON(http.StatusConflict, gin.H{"error": "Idempotency-Key was already used for a different receipt."})
+				return
+			}
+			log.Println("Idempotency-Key matched an existing receipt:", idempotencyKey, "->", record.ID)
+			context.JSON(http.StatusOK, ReceiptResponse{ID: record.ID})
+			return
+		}
+	} else if id, exists := hashToID[contentHash]; exists {
+		log.Println("Receipt content matched an existing receipt:", id)
+		context.JSON(http.StatusOK, ReceiptResponse{ID: id})
+		return
+	}
+
+	// Generate a unique identifier for the receipt.
+	id := uuid.New().String()
+	log.Println("Generated receipt ID:", id)
+
+	ctx := context.Request.Context()
+	if err := h.repo.Save(ctx, id, receipt); err != nil {
+		log.Println("Failed to save receipt:", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store receipt."})
+		return
+	}
+
+	// Calculate points and record them as the first ledger entry, so
+	// GET /receipts/:id/points sums stored ledger entries instead of
+	// re-evaluating the rules, including after a restart.
+	_, points, err := h.rules.Evaluate(receipt)
+	if err != nil {
+		log.Println("Failed to calculate receipt points:", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate points."})
+		return
+	}
+	if err := h.ledger.Record(ctx, id, LedgerKindProcess, points, "initial receipt processing", time.Now()); err != nil {
+		log.Println("Failed to record receipt points:", err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store receipt."})
+		return
+	}
+
+	// Remember the content hash (and Idempotency-Key, if provided) so
+	// resubmits are recognized.
+	hashToID[contentHash] = id
+	if idempotencyKey != "" {
+		idempotencyKeys[idempotencyKey] = idempotencyRecord{Hash: contentHash, ID: id}
+	}
+	log.Println("Receipt stored successfully.")
+
+	// Return the generated receipt ID in the response.
+	context.JSON(http.StatusOK, ReceiptResponse{ID: id})
+}
+
+// ExplainedPointsResponse is the response returned when querying the
+// points for a receipt with ?explain=1: the total plus the per-rule
+// breakdown that produced it.
+type ExplainedPointsResponse struct {
+	// Points is the receipt's current net points total, including any
+	// refund or adjustment.
+	Points int `json:"points"`
+
+	// Breakdown lists how each rule contributed to the points originally
+	// awarded when the receipt was processed; after a refund or
+	// adjustment its sum differs from Points.
+	Breakdown []RuleResult `json:"breakdown"`
+}
+
+// getPoints handles the retrieval of points awarded for a given receipt.
+// It looks up the previously calculated points for the receipt's ID and
+// returns them. With ?explain=1, it instead returns the full per-rule
+// breakdown alongside the total.
+func (h *receiptHandler) getPoints(context *gin.Context) {
+	// Retrieve the receipt ID from the request URL parameters.
+	id := context.Param("id")
+	log.Println("Fetching points for receipt ID:", id)
+
+	if explain, err := strconv.ParseBool(context.Query("explain")); err == nil && explain {
+		h.getPointsExplained(context, id)
+		return
+	}
+
+	ctx := context.Request.Context()
+
+	// If the receipt doesn't exist, return a 404 Not Found response.
+	if _, exists, err := h.repo.Get(ctx, id); err != nil {
+		log.Println("Failed to fetch receipt for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch points."})
+		return
+	} else if !exists {
+		log.Println("No receipt found for ID:", id)
+		context.JSON(http.StatusNotFound, gin.H{"error": "No receipt found for that ID"})
+		return
+	}
+
+	points, err := h.ledger.Total(ctx, id)
+	if err != nil {
+		log.Println("Failed to fetch points for receipt ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch points."})
+		return
+	}
+
+	log.Println("Points fetched for receipt ID:", id, "Total Points:", points)
+
+	// Return the current net points total (initial processing plus any
+	// refund or adjustment) in the response.
+	context.JSON(http.StatusOK, PointsResponse{Points: points})
+}
+
+// getPointsExplained handles the ?explain=1 variant of getPoints: it
+// re-evaluates the rule set against the stored receipt to explain how
+// its points were originally calculated, alongside the current net
+// total (which may since have been refunded or adjusted).
+func (h *receiptHandler) getPointsExplained(context *gin.Context, id string) {
+	ctx := context.Request.Context()
+
+	receipt, exists, err := h.repo.Get(ctx, id)
+	if err != nil {
+		log.Println("Failed to fetch receipt for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch points."})
+		return
+	}
+	if !exists {
+		log.Println("No receipt found for ID:", id)
+		context.JSON(http.StatusNotFound, gin.H{"error": "No receipt found for that ID"})
+		return
+	}
+
+	breakdown, _, err := h.rules.Evaluate(receipt)
+	if err != nil {
+		log.Println("Failed to calculate receipt points for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate points."})
+		return
+	}
+
+	total, err := h.ledger.Total(ctx, id)
+	if err != nil {
+		log.Println("Failed to fetch points for receipt ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch points."})
+		return
+	}
+
+	log.Println("Points explained for receipt ID:", id, "Total Points:", total)
+	context.JSON(http.StatusOK, ExplainedPointsResponse{Points: total, Breakdown: breakdown})
+}
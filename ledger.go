@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// PointsLedger derives a receipt's point total from the chronological
+// ledger entries recorded against it (the initial processing, any
+// refund, any adjustments), rather than a single stored scalar.
+type PointsLedger struct {
+	repo ReceiptRepository
+}
+
+// NewPointsLedger returns a PointsLedger backed by repo.
+func NewPointsLedger(repo ReceiptRepository) *PointsLedger {
+	return &PointsLedger{repo: repo}
+}
+
+// Record appends a new ledger entry for id.
+func (l *PointsLedger) Record(ctx context.Context, id string, kind string, delta int, reason string, occurredAt time.Time) error {
+	return l.repo.AppendLedgerEntry(ctx, id, LedgerEntry{
+		Timestamp: occurredAt,
+		Kind:      kind,
+		Delta:     delta,
+		Reason:    reason,
+	})
+}
+
+// Entries returns every ledger entry recorded for id, oldest first.
+func (l *PointsLedger) Entries(ctx context.Context, id string) ([]LedgerEntry, error) {
+	return l.repo.GetLedger(ctx, id)
+}
+
+// Total returns the current net point total for id: the sum of every
+// ledger entry's delta.
+func (l *PointsLedger) Total(ctx context.Context, id string) (int, error) {
+	entries, err := l.repo.GetLedger(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, entry := range entries {
+		total += entry.Delta
+	}
+	return total, nil
+}
+
+// IsRefunded reports whether a refund entry has already been recorded
+// for id.
+func (l *PointsLedger) IsRefunded(ctx context.Context, id string) (bool, error) {
+	entries, err := l.repo.GetLedger(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.Kind == LedgerKindRefund {
+			return true, nil
+		}
+	}
+	return false, nil
+}
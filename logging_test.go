@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	tests := map[string]logLevel{
+		"debug":   logLevelDebug,
+		"Debug":   logLevelDebug,
+		"info":    logLevelInfo,
+		"":        logLevelInfo,
+		"bogus":   logLevelInfo,
+		"warn":    logLevelWarn,
+		"warning": logLevelWarn,
+		"error":   logLevelError,
+	}
+	for input, want := range tests {
+		if got := parseLogLevel(input); got != want {
+			t.Errorf("parseLogLevel(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestLevelFilterWriter_DropsBelowMin(t *testing.T) {
+	var buf bytes.Buffer
+	w := &levelFilterWriter{out: &buf, min: logLevelError}
+
+	if _, err := w.Write([]byte("an info-level line\n")); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected write to be dropped, got %q", buf.String())
+	}
+}
+
+func TestLevelFilterWriter_PassesAtOrAboveMin(t *testing.T) {
+	var buf bytes.Buffer
+	w := &levelFilterWriter{out: &buf, min: logLevelInfo}
+
+	if _, err := w.Write([]byte("an info-level line\n")); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+	if buf.String() != "an info-level line\n" {
+		t.Errorf("expected write to pass through, got %q", buf.String())
+	}
+}
+
+func TestJSONLineWriter_WrapsMessageAsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	w := &jsonLineWriter{out: &buf}
+
+	if _, err := w.Write([]byte("hello world\n")); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+
+	var decoded struct {
+		Time string `json:"time"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("failed to decode JSON line %q: %v", buf.String(), err)
+	}
+	if decoded.Msg != "hello world" {
+		t.Errorf("Msg = %q, want %q", decoded.Msg, "hello world")
+	}
+	if decoded.Time == "" {
+		t.Error("expected Time to be set")
+	}
+}
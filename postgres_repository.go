@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresReceiptRepository is a ReceiptRepository backed by Postgres,
+// intended for production deployments where multiple instances of this
+// service share one database.
+type postgresReceiptRepository struct {
+	db *sql.DB
+}
+
+// newPostgresReceiptRepository opens a connection pool to the Postgres
+// database described by dataSourceName and returns a ReceiptRepository
+// backed by it. The schema in migrations/0001_init.sql and
+// migrations/postgres/0002_ledger.sql must already have been applied.
+func newPostgresReceiptRepository(dataSourceName string) (*postgresReceiptRepository, error) {
+	db, err := sql.Open("postgres", dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("opening postgres database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("connecting to postgres database: %w", err)
+	}
+	return &postgresReceiptRepository{db: db}, nil
+}
+
+func (r *postgresReceiptRepository) Save(ctx context.Context, id string, receipt Receipt) error {
+	itemsJSON, err := json.Marshal(receipt.Items)
+	if err != nil {
+		return fmt.Errorf("marshaling receipt items: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO receipts (id, retailer, purchase_date, purchase_time, total, items_json)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			retailer = excluded.retailer,
+			purchase_date = excluded.purchase_date,
+			purchase_time = excluded.purchase_time,
+			total = excluded.total,
+			items_json = excluded.items_json
+	`, id, receipt.Retailer, receipt.PurchaseDate, receipt.PurchaseTime, receipt.Total, itemsJSON)
+	if err != nil {
+		return fmt.Errorf("saving receipt %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *postgresReceiptRepository) Get(ctx context.Context, id string) (Receipt, bool, error) {
+	var receipt Receipt
+	var itemsJSON []byte
+	row := r.db.QueryRowContext(ctx, `
+		SELECT retailer, purchase_date, purchase_time, total, items_json
+		FROM receipts WHERE id = $1
+	`, id)
+	if err := row.Scan(&receipt.Retailer, &receipt.PurchaseDate, &receipt.PurchaseTime, &receipt.Total, &itemsJSON); err != nil {
+		if err == sql.ErrNoRows {
+			return Receipt{}, false, nil
+		}
+		return Receipt{}, false, fmt.Errorf("fetching receipt %s: %w", id, err)
+	}
+	if err := json.Unmarshal(itemsJSON, &receipt.Items); err != nil {
+		return Receipt{}, false, fmt.Errorf("unmarshaling receipt items for %s: %w", id, err)
+	}
+	return receipt, true, nil
+}
+
+func (r *postgresReceiptRepository) AppendLedgerEntry(ctx context.Context, id string, entry LedgerEntry) error {
+	// seq is a BIGSERIAL assigned atomically by Postgres (see
+	// migrations/postgres/0002_ledger.sql), so two instances appending to
+	// the same receipt at once can't collide the way a read-then-write
+	// MAX() here could.
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO ledger_entries (id, occurred_at, kind, delta, reason)
+		VALUES ($1, $2, $3, $4, $5)
+	`, id, entry.Timestamp.UTC(), entry.Kind, entry.Delta, entry.Reason)
+	if err != nil {
+		return fmt.Errorf("appending ledger entry for %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *postgresReceiptRepository) GetLedger(ctx context.Context, id string) ([]LedgerEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT occurred_at, kind, delta, reason FROM ledger_entries
+		WHERE id = $1 ORDER BY seq ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("fetching ledger for %s: %w", id, err)
+	}
+	defer rows.Close()
+
+	entries := []LedgerEntry{}
+	for rows.Next() {
+		var entry LedgerEntry
+		var occurredAt time.Time
+		if err := rows.Scan(&occurredAt, &entry.Kind, &entry.Delta, &entry.Reason); err != nil {
+			return nil, fmt.Errorf("scanning ledger entry for %s: %w", id, err)
+		}
+		entry.Timestamp = occurredAt
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading ledger for %s: %w", id, err)
+	}
+	return entries, nil
+}
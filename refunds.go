@@ -0,0 +1,161 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdjustRequest is the body of POST /receipts/:id/adjust.
+type AdjustRequest struct {
+	// Delta is the number of points to add (or, if negative, subtract).
+	Delta int `json:"delta"`
+
+	// Reason explains why the adjustment was made.
+	Reason string `json:"reason" binding:"required"`
+}
+
+// LedgerResponse is the response returned by GET /receipts/:id/ledger.
+type LedgerResponse struct {
+	// Entries lists the receipt's ledger entries in chronological order.
+	Entries []LedgerEntry `json:"entries"`
+}
+
+// refundReceipt handles POST /receipts/:id/refund. It records a ledger
+// entry that negates the receipt's current net points total. A receipt
+// can only be refunded once; a second refund attempt is rejected with
+// 409 Conflict.
+func (h *receiptHandler) refundReceipt(context *gin.Context) {
+	id := context.Param("id")
+	ctx := context.Request.Context()
+
+	// storeLock also guards the ledger's refund state machine so
+	// concurrent refund attempts against the same ID can't both
+	// observe "not yet refunded" and both proceed.
+	storeLock.Lock()
+	defer storeLock.Unlock()
+
+	if _, exists, err := h.repo.Get(ctx, id); err != nil {
+		log.Println("Failed to fetch receipt for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refund receipt."})
+		return
+	} else if !exists {
+		log.Println("No receipt found for ID:", id)
+		context.JSON(http.StatusNotFound, gin.H{"error": "No receipt found for that ID"})
+		return
+	}
+
+	refunded, err := h.ledger.IsRefunded(ctx, id)
+	if err != nil {
+		log.Println("Failed to check refund status for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refund receipt."})
+		return
+	}
+	if refunded {
+		log.Println("Receipt already refunded:", id)
+		context.JSON(http.StatusConflict, gin.H{"error": "Receipt has already been refunded."})
+		return
+	}
+
+	total, err := h.ledger.Total(ctx, id)
+	if err != nil {
+		log.Println("Failed to fetch points total for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refund receipt."})
+		return
+	}
+
+	if err := h.ledger.Record(ctx, id, LedgerKindRefund, -total, "refund", time.Now()); err != nil {
+		log.Println("Failed to record refund for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refund receipt."})
+		return
+	}
+
+	log.Println("Refunded receipt:", id)
+	context.JSON(http.StatusOK, PointsResponse{Points: 0})
+}
+
+// adjustReceipt handles POST /receipts/:id/adjust. It records a manual
+// ledger entry that adds (or, if negative, subtracts) delta points.
+// Adjustments are rejected with 409 Conflict once a receipt has been
+// refunded.
+func (h *receiptHandler) adjustReceipt(context *gin.Context) {
+	id := context.Param("id")
+	ctx := context.Request.Context()
+
+	var req AdjustRequest
+	if err := context.ShouldBindJSON(&req); err != nil {
+		log.Println("Failed to bind adjust request:", err)
+		context.JSON(http.StatusBadRequest, gin.H{"error": "The adjustment request is invalid."})
+		return
+	}
+
+	storeLock.Lock()
+	defer storeLock.Unlock()
+
+	if _, exists, err := h.repo.Get(ctx, id); err != nil {
+		log.Println("Failed to fetch receipt for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust receipt."})
+		return
+	} else if !exists {
+		log.Println("No receipt found for ID:", id)
+		context.JSON(http.StatusNotFound, gin.H{"error": "No receipt found for that ID"})
+		return
+	}
+
+	refunded, err := h.ledger.IsRefunded(ctx, id)
+	if err != nil {
+		log.Println("Failed to check refund status for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust receipt."})
+		return
+	}
+	if refunded {
+		log.Println("Cannot adjust already-refunded receipt:", id)
+		context.JSON(http.StatusConflict, gin.H{"error": "Receipt has already been refunded."})
+		return
+	}
+
+	if err := h.ledger.Record(ctx, id, LedgerKindAdjust, req.Delta, req.Reason, time.Now()); err != nil {
+		log.Println("Failed to record adjustment for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust receipt."})
+		return
+	}
+
+	total, err := h.ledger.Total(ctx, id)
+	if err != nil {
+		log.Println("Failed to fetch points total for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust receipt."})
+		return
+	}
+
+	log.Println("Adjusted receipt:", id, "by", req.Delta, "points. New total:", total)
+	context.JSON(http.StatusOK, PointsResponse{Points: total})
+}
+
+// getLedger handles GET /receipts/:id/ledger, returning the receipt's
+// full chronological ledger of processing, refund, and adjustment
+// entries.
+func (h *receiptHandler) getLedger(context *gin.Context) {
+	id := context.Param("id")
+	ctx := context.Request.Context()
+
+	if _, exists, err := h.repo.Get(ctx, id); err != nil {
+		log.Println("Failed to fetch receipt for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ledger."})
+		return
+	} else if !exists {
+		log.Println("No receipt found for ID:", id)
+		context.JSON(http.StatusNotFound, gin.H{"error": "No receipt found for that ID"})
+		return
+	}
+
+	entries, err := h.ledger.Entries(ctx, id)
+	if err != nil {
+		log.Println("Failed to fetch ledger for ID:", id, err)
+		context.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ledger."})
+		return
+	}
+
+	context.JSON(http.StatusOK, LedgerResponse{Entries: entries})
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouterWithRefunds builds a fresh Gin router wired to a
+// receiptHandler with the refund/adjust/ledger endpoints included, and
+// resets the package-level idempotency caches so tests don't bleed into
+// each other.
+func newTestRouterWithRefunds() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	hashToID = make(map[string]string)
+	idempotencyKeys = make(map[string]idempotencyRecord)
+
+	handler := newReceiptHandler(newMemoryReceiptRepository(), DefaultRuleSet())
+
+	router := gin.New()
+	router.POST("/receipts/process", handler.processReceipt)
+	router.GET("/receipts/:id/points", handler.getPoints)
+	router.POST("/receipts/:id/refund", handler.refundReceipt)
+	router.POST("/receipts/:id/adjust", handler.adjustReceipt)
+	router.GET("/receipts/:id/ledger", handler.getLedger)
+	return router
+}
+
+func processSampleReceipt(t *testing.T, router *gin.Engine) string {
+	t.Helper()
+	resp := postReceipt(router, sampleReceipt, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200 processing sample receipt, got %d", resp.Code)
+	}
+	return decodeReceiptResponse(t, resp).ID
+}
+
+func getPointsTotal(t *testing.T, router *gin.Engine, id string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/receipts/"+id+"/points", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET points returned %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var resp PointsResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode points response: %v", err)
+	}
+	return resp.Points
+}
+
+func postRefund(router *gin.Engine, id string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/receipts/"+id+"/refund", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func postAdjust(router *gin.Engine, id string, delta int, reason string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(AdjustRequest{Delta: delta, Reason: reason})
+	req := httptest.NewRequest(http.MethodPost, "/receipts/"+id+"/adjust", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRefundReceipt_ZeroesOutPoints(t *testing.T) {
+	router := newTestRouterWithRefunds()
+	id := processSampleReceipt(t, router)
+
+	before := getPointsTotal(t, router, id)
+	if before == 0 {
+		t.Fatal("expected sample receipt to have earned some points")
+	}
+
+	refund := postRefund(router, id)
+	if refund.Code != http.StatusOK {
+		t.Fatalf("expected 200 refunding receipt, got %d: %s", refund.Code, refund.Body.String())
+	}
+
+	after := getPointsTotal(t, router, id)
+	if after != 0 {
+		t.Fatalf("expected 0 points after refund, got %d", after)
+	}
+}
+
+func TestRefundReceipt_DoubleRefundConflicts(t *testing.T) {
+	router := newTestRouterWithRefunds()
+	id := processSampleReceipt(t, router)
+
+	if resp := postRefund(router, id); resp.Code != http.StatusOK {
+		t.Fatalf("expected 200 on first refund, got %d", resp.Code)
+	}
+
+	resp := postRefund(router, id)
+	if resp.Code != http.StatusConflict {
+		t.Fatalf("expected 409 on second refund, got %d", resp.Code)
+	}
+}
+
+func TestAdjustReceipt_ThenRefund(t *testing.T) {
+	router := newTestRouterWithRefunds()
+	id := processSampleReceipt(t, router)
+
+	before := getPointsTotal(t, router, id)
+
+	adjust := postAdjust(router, id, 10, "customer service goodwill credit")
+	if adjust.Code != http.StatusOK {
+		t.Fatalf("expected 200 adjusting receipt, got %d: %s", adjust.Code, adjust.Body.String())
+	}
+
+	afterAdjust := getPointsTotal(t, router, id)
+	if afterAdjust != before+10 {
+		t.Fatalf("expected %d points after +10 adjustment, got %d", before+10, afterAdjust)
+	}
+
+	refund := postRefund(router, id)
+	if refund.Code != http.StatusOK {
+		t.Fatalf("expected 200 refunding adjusted receipt, got %d: %s", refund.Code, refund.Body.String())
+	}
+
+	afterRefund := getPointsTotal(t, router, id)
+	if afterRefund != 0 {
+		t.Fatalf("expected 0 points after refunding an adjusted receipt, got %d", afterRefund)
+	}
+}
+
+func TestAdjustReceipt_RejectedAfterRefund(t *testing.T) {
+	router := newTestRouterWithRefunds()
+	id := processSampleReceipt(t, router)
+
+	if resp := postRefund(router, id); resp.Code != http.StatusOK {
+		t.Fatalf("expected 200 refunding receipt, got %d", resp.Code)
+	}
+
+	resp := postAdjust(router, id, 5, "too late")
+	if resp.Code != http.StatusConflict {
+		t.Fatalf("expected 409 adjusting an already-refunded receipt, got %d", resp.Code)
+	}
+}
+
+func TestGetLedger_RecordsEveryEvent(t *testing.T) {
+	router := newTestRouterWithRefunds()
+	id := processSampleReceipt(t, router)
+	postAdjust(router, id, 10, "goodwill credit")
+	postRefund(router, id)
+
+	req := httptest.NewRequest(http.MethodGet, "/receipts/"+id+"/ledger", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET ledger returned %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var resp LedgerResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode ledger response: %v", err)
+	}
+	if len(resp.Entries) != 3 {
+		t.Fatalf("expected 3 ledger entries (process, adjust, refund), got %d: %+v", len(resp.Entries), resp.Entries)
+	}
+	wantKinds := []string{LedgerKindProcess, LedgerKindAdjust, LedgerKindRefund}
+	for i, kind := range wantKinds {
+		if resp.Entries[i].Kind != kind {
+			t.Errorf("entry %d kind = %q, want %q", i, resp.Entries[i].Kind, kind)
+		}
+	}
+}
+
+func TestRefundReceipt_ConcurrentAttemptsOnlyOneSucceeds(t *testing.T) {
+	router := newTestRouterWithRefunds()
+	id := processSampleReceipt(t, router)
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	codes := make([]int, attempts)
+	wg.Add(attempts)
+	for i := 0; i < attempts; i++ {
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = postRefund(router, id).Code
+		}(i)
+	}
+	wg.Wait()
+
+	successes := 0
+	for _, code := range codes {
+		if code == http.StatusOK {
+			successes++
+		} else if code != http.StatusConflict {
+			t.Errorf("unexpected status code from concurrent refund: %d", code)
+		}
+	}
+	if successes != 1 {
+		t.Fatalf("expected exactly 1 successful refund among %d concurrent attempts, got %d", attempts, successes)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrReceiptNotFound is an error value for a missing receipt. The
+// ReceiptRepository implementations in this package do not return it:
+// Get reports a missing receipt through its boolean result instead.
+var ErrReceiptNotFound = errors.New("receipt not found")
+
+// Ledger entry kinds recorded by PointsLedger.
+const (
+	LedgerKindProcess = "process"
+	LedgerKindRefund  = "refund"
+	LedgerKindAdjust  = "adjust"
+)
+
+// LedgerEntry is one chronological event affecting a receipt's points
+// total: the initial processing, a refund, or a manual adjustment.
+type LedgerEntry struct {
+	// Timestamp is when the entry was recorded.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Kind is one of LedgerKindProcess, LedgerKindRefund, or LedgerKindAdjust.
+	Kind string `json:"kind"`
+
+	// Delta is the change in points this entry applies (negative for
+	// refunds, either sign for adjustments).
+	Delta int `json:"delta"`
+
+	// Reason explains why the entry was recorded.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ReceiptRepository persists receipts and the ledger of point-affecting
+// events recorded against them. It is the seam between the HTTP handlers
+// and whatever storage backend is configured (in-memory, SQLite, or
+// Postgres), so handlers never talk to a concrete store directly.
+type ReceiptRepository interface {
+	// Save stores a receipt under the given ID, overwriting any existing
+	// receipt with that ID.
+	Save(ctx context.Context, id string, receipt Receipt) error
+
+	// Get returns the receipt stored under id. The second return value
+	// is false if no receipt exists for that ID.
+	Get(ctx context.Context, id string) (Receipt, bool, error)
+
+	// AppendLedgerEntry records a new ledger entry for id.
+	AppendLedgerEntry(ctx context.Context, id string, entry LedgerEntry) error
+
+	// GetLedger returns every ledger entry recorded for id, in the order
+	// they were appended. It returns an empty slice if none exist.
+	GetLedger(ctx context.Context, id string) ([]LedgerEntry, error)
+}
+
+// memoryReceiptRepository is an in-memory ReceiptRepository. It's the
+// default backend and matches the original behavior of this service:
+// data lives only as long as the process does.
+type memoryReceiptRepository struct {
+	mu       sync.RWMutex
+	receipts map[string]Receipt
+	ledgers  map[string][]LedgerEntry
+}
+
+// newMemoryReceiptRepository returns a ReceiptRepository backed by
+// process memory.
+func newMemoryReceiptRepository() *memoryReceiptRepository {
+	return &memoryReceiptRepository{
+		receipts: make(map[string]Receipt),
+		ledgers:  make(map[string][]LedgerEntry),
+	}
+}
+
+func (r *memoryReceiptRepository) Save(ctx context.Context, id string, receipt Receipt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.receipts[id] = receipt
+	return nil
+}
+
+func (r *memoryReceiptRepository) Get(ctx context.Context, id string) (Receipt, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	receipt, exists := r.receipts[id]
+	return receipt, exists, nil
+}
+
+func (r *memoryReceiptRepository) AppendLedgerEntry(ctx context.Context, id string, entry LedgerEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ledgers[id] = append(r.ledgers[id], entry)
+	return nil
+}
+
+func (r *memoryReceiptRepository) GetLedger(ctx context.Context, id string) ([]LedgerEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := make([]LedgerEntry, len(r.ledgers[id]))
+	copy(entries, r.ledgers[id])
+	return entries, nil
+}
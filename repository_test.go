@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// repositoryFactories lists the ReceiptRepository backends that should
+// behave identically from the caller's point of view.
+func repositoryFactories(t *testing.T) map[string]func() ReceiptRepository {
+	t.Helper()
+	return map[string]func() ReceiptRepository{
+		"memory": func() ReceiptRepository {
+			return newMemoryReceiptRepository()
+		},
+		"sqlite": func() ReceiptRepository {
+			// A private in-memory database per test keeps runs isolated.
+			repo, err := newSQLiteReceiptRepository("file::memory:?cache=private")
+			if err != nil {
+				t.Fatalf("failed to open sqlite repository: %v", err)
+			}
+			return repo
+		},
+	}
+}
+
+func TestReceiptRepository_WriteThenRead(t *testing.T) {
+	for name, newRepo := range repositoryFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo()
+			ctx := context.Background()
+
+			receipt := Receipt{
+				Retailer:     "Target",
+				PurchaseDate: "2022-01-01",
+				PurchaseTime: "13:01",
+				Total:        "18.74",
+				Items: []Item{
+					{ShortDescription: "Mountain Dew 12PK", Price: "6.49"},
+				},
+			}
+
+			if err := repo.Save(ctx, "receipt-1", receipt); err != nil {
+				t.Fatalf("Save() returned error: %v", err)
+			}
+
+			got, exists, err := repo.Get(ctx, "receipt-1")
+			if err != nil {
+				t.Fatalf("Get() returned error: %v", err)
+			}
+			if !exists {
+				t.Fatal("expected receipt to exist after Save()")
+			}
+			if got.Retailer != receipt.Retailer || got.Total != receipt.Total || len(got.Items) != 1 {
+				t.Fatalf("Get() returned %+v, want %+v", got, receipt)
+			}
+
+			if err := repo.AppendLedgerEntry(ctx, "receipt-1", LedgerEntry{
+				Timestamp: time.Now(),
+				Kind:      LedgerKindProcess,
+				Delta:     42,
+				Reason:    "initial receipt processing",
+			}); err != nil {
+				t.Fatalf("AppendLedgerEntry() returned error: %v", err)
+			}
+
+			entries, err := repo.GetLedger(ctx, "receipt-1")
+			if err != nil {
+				t.Fatalf("GetLedger() returned error: %v", err)
+			}
+			if len(entries) != 1 || entries[0].Delta != 42 || entries[0].Kind != LedgerKindProcess {
+				t.Fatalf("GetLedger() = %+v, want a single process entry worth 42", entries)
+			}
+
+			if _, exists, err := repo.Get(ctx, "missing"); err != nil || exists {
+				t.Fatalf("Get() for missing ID = (exists=%v, err=%v), want (false, nil)", exists, err)
+			}
+			if entries, err := repo.GetLedger(ctx, "missing"); err != nil || len(entries) != 0 {
+				t.Fatalf("GetLedger() for missing ID = (%v, err=%v), want (empty, nil)", entries, err)
+			}
+		})
+	}
+}
+
+func TestReceiptRepository_ConcurrentWriters(t *testing.T) {
+	for name, newRepo := range repositoryFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo()
+			ctx := context.Background()
+
+			const writers = 20
+			var wg sync.WaitGroup
+			wg.Add(writers)
+			for i := 0; i < writers; i++ {
+				go func(i int) {
+					defer wg.Done()
+					id := fmt.Sprintf("receipt-%d", i)
+					receipt := Receipt{
+						Retailer:     fmt.Sprintf("Retailer %d", i),
+						PurchaseDate: "2022-01-01",
+						PurchaseTime: "13:01",
+						Total:        "1.00",
+					}
+					if err := repo.Save(ctx, id, receipt); err != nil {
+						t.Errorf("Save(%s) returned error: %v", id, err)
+						return
+					}
+					if err := repo.AppendLedgerEntry(ctx, id, LedgerEntry{
+						Timestamp: time.Now(),
+						Kind:      LedgerKindProcess,
+						Delta:     i,
+						Reason:    "initial receipt processing",
+					}); err != nil {
+						t.Errorf("AppendLedgerEntry(%s) returned error: %v", id, err)
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			for i := 0; i < writers; i++ {
+				id := fmt.Sprintf("receipt-%d", i)
+				entries, err := repo.GetLedger(ctx, id)
+				if err != nil {
+					t.Fatalf("GetLedger(%s) returned error: %v", id, err)
+				}
+				if len(entries) != 1 || entries[0].Delta != i {
+					t.Fatalf("GetLedger(%s) = %+v, want a single entry worth %d", id, entries, i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// alphanumericRegex matches the characters AlphanumRetailerRule counts.
+var alphanumericRegex = regexp.MustCompile(`[a-zA-Z0-9]`)
+
+// Rule awards points for one aspect of a receipt. Rules are composed into
+// a RuleSet so that operators can add, remove, or reparameterize them
+// without touching Go code.
+type Rule interface {
+	// Name identifies the rule in a RuleResult breakdown.
+	Name() string
+
+	// Apply evaluates the rule against a receipt, returning the points
+	// it awards and a human-readable explanation of why.
+	Apply(receipt Receipt) (points int, explanation string, err error)
+}
+
+// RuleResult is one line of a points breakdown: which rule ran, how many
+// points it awarded, and why.
+type RuleResult struct {
+	Name        string `json:"name"`
+	Points      int    `json:"points"`
+	Explanation string `json:"explanation"`
+}
+
+// RuleSet is an ordered pipeline of Rules. Its total is the sum of every
+// rule's awarded points.
+type RuleSet struct {
+	rules []Rule
+}
+
+// NewRuleSet returns a RuleSet that evaluates rules in order.
+func NewRuleSet(rules ...Rule) *RuleSet {
+	return &RuleSet{rules: rules}
+}
+
+// Evaluate runs every rule in the set against receipt and returns the
+// per-rule breakdown along with the total points awarded.
+func (rs *RuleSet) Evaluate(receipt Receipt) ([]RuleResult, int, error) {
+	breakdown := make([]RuleResult, 0, len(rs.rules))
+	total := 0
+	for _, rule := range rs.rules {
+		points, explanation, err := rule.Apply(receipt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("rule %s: %w", rule.Name(), err)
+		}
+		breakdown = append(breakdown, RuleResult{Name: rule.Name(), Points: points, Explanation: explanation})
+		total += points
+	}
+	return breakdown, total, nil
+}
+
+// AlphanumRetailerRule awards one point for every alphanumeric character
+// in the retailer name.
+type AlphanumRetailerRule struct{}
+
+func (AlphanumRetailerRule) Name() string { return "alphanumeric_retailer" }
+
+func (AlphanumRetailerRule) Apply(receipt Receipt) (int, string, error) {
+	count := len(alphanumericRegex.FindAllString(receipt.Retailer, -1))
+	return count, fmt.Sprintf("%d point(s) - one per alphanumeric character in the retailer name", count), nil
+}
+
+// RoundTotalRule awards a fixed bonus if the receipt total is a round
+// dollar amount with no cents.
+type RoundTotalRule struct {
+	Points int `toml:"points"`
+}
+
+func (RoundTotalRule) Name() string { return "round_total" }
+
+func (r RoundTotalRule) Apply(receipt Receipt) (int, string, error) {
+	if strings.HasSuffix(receipt.Total, ".00") {
+		return r.Points, fmt.Sprintf("%d point(s) - total is a round dollar amount", r.Points), nil
+	}
+	return 0, "0 points - total is not a round dollar amount", nil
+}
+
+// QuarterMultipleRule awards a fixed bonus if the receipt total is a
+// multiple of $0.25.
+type QuarterMultipleRule struct {
+	Points int `toml:"points"`
+}
+
+func (QuarterMultipleRule) Name() string { return "quarter_multiple" }
+
+func (r QuarterMultipleRule) Apply(receipt Receipt) (int, string, error) {
+	totalInCents, _ := strconv.Atoi(strings.ReplaceAll(receipt.Total, ".", ""))
+	if totalInCents%25 == 0 {
+		return r.Points, fmt.Sprintf("%d point(s) - total is a multiple of $0.25", r.Points), nil
+	}
+	return 0, "0 points - total is not a multiple of $0.25", nil
+}
+
+// ItemPairRule awards a fixed number of points for every two items on
+// the receipt.
+type ItemPairRule struct {
+	PointsPerPair int `toml:"points_per_pair"`
+}
+
+func (ItemPairRule) Name() string { return "item_pair" }
+
+func (r ItemPairRule) Apply(receipt Receipt) (int, string, error) {
+	pairs := len(receipt.Items) / 2
+	points := pairs * r.PointsPerPair
+	return points, fmt.Sprintf("%d point(s) - %d pair(s) of items at %d point(s) each", points, pairs, r.PointsPerPair), nil
+}
+
+// DescriptionLengthMultipleRule awards points for each item whose
+// trimmed short description length is a multiple of Modulus: the item's
+// price multiplied by Multiplier, rounded up to the nearest integer.
+type DescriptionLengthMultipleRule struct {
+	Modulus    int     `toml:"modulus"`
+	Multiplier float64 `toml:"multiplier"`
+}
+
+func (DescriptionLengthMultipleRule) Name() string { return "description_length_multiple" }
+
+func (r DescriptionLengthMultipleRule) Apply(receipt Receipt) (int, string, error) {
+	total := 0
+	matched := 0
+	for _, item := range receipt.Items {
+		trimmedLen := len(strings.TrimSpace(item.ShortDescription))
+		if r.Modulus == 0 || trimmedLen%r.Modulus != 0 {
+			continue
+		}
+		price, _ := strconv.ParseFloat(item.Price, 64)
+		total += int(math.Ceil(price * r.Multiplier))
+		matched++
+	}
+	return total, fmt.Sprintf("%d point(s) - %d item(s) with description length a multiple of %d", total, matched, r.Modulus), nil
+}
+
+// OddDayRule awards a fixed bonus if the day of the purchase date is odd.
+type OddDayRule struct {
+	Bonus int `toml:"bonus"`
+}
+
+func (OddDayRule) Name() string { return "odd_day" }
+
+func (r OddDayRule) Apply(receipt Receipt) (int, string, error) {
+	dateParts := strings.Split(receipt.PurchaseDate, "-")
+	if len(dateParts) != 3 {
+		return 0, "0 points - purchase date could not be parsed", nil
+	}
+	day, err := strconv.Atoi(dateParts[2])
+	if err != nil || day%2 == 0 {
+		return 0, "0 points - purchase day is not odd", nil
+	}
+	return r.Bonus, fmt.Sprintf("%d point(s) - purchase day is odd", r.Bonus), nil
+}
+
+// TimeWindowRule awards a fixed bonus if the purchase time falls
+// strictly between Start and End (both "HH:MM", 24-hour).
+type TimeWindowRule struct {
+	Start string `toml:"start"`
+	End   string `toml:"end"`
+	Bonus int    `toml:"bonus"`
+}
+
+func (TimeWindowRule) Name() string { return "time_window" }
+
+func (r TimeWindowRule) Apply(receipt Receipt) (int, string, error) {
+	start, err := time.Parse("15:04", r.Start)
+	if err != nil {
+		return 0, "", fmt.Errorf("parsing start time %q: %w", r.Start, err)
+	}
+	end, err := time.Parse("15:04", r.End)
+	if err != nil {
+		return 0, "", fmt.Errorf("parsing end time %q: %w", r.End, err)
+	}
+	purchaseTime, err := time.Parse("15:04", receipt.PurchaseTime)
+	if err != nil {
+		return 0, "0 points - purchase time could not be parsed", nil
+	}
+	if purchaseTime.After(start) && purchaseTime.Before(end) {
+		return r.Bonus, fmt.Sprintf("%d point(s) - purchase time is between %s and %s", r.Bonus, r.Start, r.End), nil
+	}
+	return 0, fmt.Sprintf("0 points - purchase time is not between %s and %s", r.Start, r.End), nil
+}
+
+// DefaultRuleSet returns the built-in RuleSet matching this service's
+// original, hard-coded point values. It's used whenever no rules file
+// is configured.
+func DefaultRuleSet() *RuleSet {
+	return NewRuleSet(
+		AlphanumRetailerRule{},
+		RoundTotalRule{Points: 50},
+		QuarterMultipleRule{Points: 25},
+		ItemPairRule{PointsPerPair: 5},
+		DescriptionLengthMultipleRule{Modulus: 3, Multiplier: 0.2},
+		OddDayRule{Bonus: 6},
+		TimeWindowRule{Start: "14:00", End: "16:00", Bonus: 10},
+	)
+}
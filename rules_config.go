@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ruleEntry is the TOML representation of a single configured rule. Only
+// the fields relevant to Type are expected to be set; the rest are
+// ignored.
+type ruleEntry struct {
+	Type          string  `toml:"type"`
+	Points        int     `toml:"points"`
+	PointsPerPair int     `toml:"points_per_pair"`
+	Modulus       int     `toml:"modulus"`
+	Multiplier    float64 `toml:"multiplier"`
+	Bonus         int     `toml:"bonus"`
+	Start         string  `toml:"start"`
+	End           string  `toml:"end"`
+}
+
+// rulesFile is the top-level shape of a rules TOML file: a list of
+// [[rule]] tables, evaluated in the order they appear.
+type rulesFile struct {
+	Rule []ruleEntry `toml:"rule"`
+}
+
+// LoadRuleSet reads a rules TOML file at path and builds the RuleSet it
+// describes. See config.example.toml's sibling rules.example.toml for
+// the expected format.
+func LoadRuleSet(path string) (*RuleSet, error) {
+	var file rulesFile
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return nil, fmt.Errorf("reading rules file %s: %w", path, err)
+	}
+
+	rules := make([]Rule, 0, len(file.Rule))
+	for i, entry := range file.Rule {
+		rule, err := buildRule(entry)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		rules = append(rules, rule)
+	}
+	return NewRuleSet(rules...), nil
+}
+
+// buildRule constructs the concrete Rule a ruleEntry describes.
+func buildRule(entry ruleEntry) (Rule, error) {
+	switch entry.Type {
+	case "alphanumeric_retailer":
+		return AlphanumRetailerRule{}, nil
+	case "round_total":
+		return RoundTotalRule{Points: entry.Points}, nil
+	case "quarter_multiple":
+		return QuarterMultipleRule{Points: entry.Points}, nil
+	case "item_pair":
+		return ItemPairRule{PointsPerPair: entry.PointsPerPair}, nil
+	case "description_length_multiple":
+		return DescriptionLengthMultipleRule{Modulus: entry.Modulus, Multiplier: entry.Multiplier}, nil
+	case "odd_day":
+		return OddDayRule{Bonus: entry.Bonus}, nil
+	case "time_window":
+		return TimeWindowRule{Start: entry.Start, End: entry.End, Bonus: entry.Bonus}, nil
+	default:
+		return nil, fmt.Errorf("unknown rule type %q", entry.Type)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import "testing"
+
+// goldenReceipts pairs receipts with the point totals the original,
+// hard-coded calculatePoints implementation produced for them. The
+// rule-engine refactor must keep reproducing these totals exactly.
+var goldenReceipts = []struct {
+	name    string
+	receipt Receipt
+	want    int
+}{
+	{
+		name: "target receipt",
+		receipt: Receipt{
+			Retailer:     "Target",
+			PurchaseDate: "2022-01-01",
+			PurchaseTime: "13:01",
+			Total:        "35.35",
+			Items: []Item{
+				{ShortDescription: "Mountain Dew 12PK", Price: "6.49"},
+				{ShortDescription: "Emils Cheese Pizza", Price: "12.25"},
+				{ShortDescription: "Knorr Creamy Chicken", Price: "1.26"},
+				{ShortDescription: "Doritos Nacho Cheese", Price: "3.35"},
+				{ShortDescription: "   Klarbrunn 12-PK 12 FL OZ  ", Price: "12.00"},
+			},
+		},
+		want: 28,
+	},
+	{
+		name: "M&M corner market receipt",
+		receipt: Receipt{
+			Retailer:     "M&M Corner Market",
+			PurchaseDate: "2022-03-20",
+			PurchaseTime: "14:33",
+			Total:        "9.00",
+			Items: []Item{
+				{ShortDescription: "Gatorade", Price: "2.25"},
+				{ShortDescription: "Gatorade", Price: "2.25"},
+				{ShortDescription: "Gatorade", Price: "2.25"},
+				{ShortDescription: "Gatorade", Price: "2.25"},
+			},
+		},
+		want: 109,
+	},
+	{
+		name: "no items, even day, outside time window",
+		receipt: Receipt{
+			Retailer:     "AB",
+			PurchaseDate: "2022-01-02",
+			PurchaseTime: "09:00",
+			Total:        "10.00",
+		},
+		want: 77,
+	},
+}
+
+func TestDefaultRuleSet_MatchesGoldenTotals(t *testing.T) {
+	rules := DefaultRuleSet()
+	for _, tc := range goldenReceipts {
+		t.Run(tc.name, func(t *testing.T) {
+			_, total, err := rules.Evaluate(tc.receipt)
+			if err != nil {
+				t.Fatalf("Evaluate() returned error: %v", err)
+			}
+			if total != tc.want {
+				t.Fatalf("Evaluate() total = %d, want %d", total, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadRuleSet_MatchesDefault(t *testing.T) {
+	loaded, err := LoadRuleSet("rules.example.toml")
+	if err != nil {
+		t.Fatalf("LoadRuleSet() returned error: %v", err)
+	}
+
+	defaults := DefaultRuleSet()
+	for _, tc := range goldenReceipts {
+		t.Run(tc.name, func(t *testing.T) {
+			_, loadedTotal, err := loaded.Evaluate(tc.receipt)
+			if err != nil {
+				t.Fatalf("Evaluate() (loaded) returned error: %v", err)
+			}
+			_, defaultTotal, err := defaults.Evaluate(tc.receipt)
+			if err != nil {
+				t.Fatalf("Evaluate() (default) returned error: %v", err)
+			}
+			if loadedTotal != defaultTotal {
+				t.Fatalf("loaded rules total = %d, default rules total = %d", loadedTotal, defaultTotal)
+			}
+		})
+	}
+}
+
+func TestLoadRuleSet_UnknownRuleType(t *testing.T) {
+	if _, err := buildRule(ruleEntry{Type: "not_a_real_rule"}); err == nil {
+		t.Fatal("expected buildRule() to reject an unknown rule type")
+	}
+}
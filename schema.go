@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+)
+
+// sqliteMigrations embeds this service's SQLite schema migrations so the
+// sqlite backend can self-initialize on open, rather than depending on a
+// separate migration step having been run against the database file.
+//
+//go:embed migrations/0001_init.sql migrations/sqlite/0002_ledger.sql
+var sqliteMigrations embed.FS
+
+// sqliteMigrationFiles lists the embedded migrations in the order they
+// must be applied.
+var sqliteMigrationFiles = []string{
+	"migrations/0001_init.sql",
+	"migrations/sqlite/0002_ledger.sql",
+}
+
+// applySQLiteMigrations applies sqliteMigrations to db, in order. Each
+// migration is idempotent (CREATE TABLE IF NOT EXISTS / DROP TABLE IF
+// EXISTS), so it's safe to run on every startup, including against a
+// database that already has the schema.
+func applySQLiteMigrations(ctx context.Context, db *sql.DB) error {
+	for _, path := range sqliteMigrationFiles {
+		contents, err := sqliteMigrations.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading migration %s: %w", path, err)
+		}
+		if _, err := db.ExecContext(ctx, string(contents)); err != nil {
+			return fmt.Errorf("applying migration %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -1,17 +1,20 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
 	"log"
-	"math"
 	"net/http"
-	"regexp"
-	"strconv"
+	"os/signal"
+	"sort"
 	"strings"
 	"sync"
-	"time"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 // Receipt represents a purchase receipt containing transaction details.
@@ -54,142 +57,143 @@ type PointsResponse struct {
 	Points int `json:"points"`
 }
 
-// receiptStore stores receipts mapped by their unique ID.
-var receiptStore = make(map[string]Receipt)
+// hashToID maps a receipt's canonical content hash to the ID it was
+// originally stored under, so resubmitting the same receipt is idempotent.
+var hashToID = make(map[string]string)
 
-// storeLock to prevent concurrent access to receiptStore.
-var storeLock sync.Mutex
+// idempotencyRecord remembers which content hash an Idempotency-Key was
+// first used with, so the key can't silently be replayed against a
+// different receipt body.
+type idempotencyRecord struct {
+	Hash string
+	ID   string
+}
 
-// main initializes the Gin router, defines the API endpoints, and starts the server.
-func main() {
-	router := gin.Default()
+// idempotencyKeys maps an Idempotency-Key header value to the record of
+// the receipt it was first used to create.
+var idempotencyKeys = make(map[string]idempotencyRecord)
 
-	// Endpoint to submit a receipt for processing.
-	router.POST("/receipts/process", processReceipt)
+// storeLock guards hashToID and idempotencyKeys.
+var storeLock sync.RWMutex
 
-	// Endpoint to retrieve the points awarded for a receipt.
-	router.GET("/receipts/:id/points", getPoints)
-
-	// Start the HTTP server on port 8080.
-	router.Run(":8080")
-}
-
-// processReceipt handles the submission of a receipt.
-// It parses the JSON request body, generates a unique receipt ID,
-// maps the ID to a receipt, and returns the ID.
-func processReceipt(context *gin.Context) {
-	var receipt Receipt
+// main loads configuration, initializes the Gin router, defines the API
+// endpoints, and runs the server until it receives SIGINT/SIGTERM, at
+// which point it shuts down gracefully.
+func main() {
+	configPath := flag.String("config", "", "path to a TOML config file (optional; see config.example.toml)")
+	rulesPath := flag.String("rules", "", "path to a points rules TOML file (optional; see rules.example.toml). Defaults to the built-in rules")
+	flag.Parse()
 
-	// If the JSON is invalid, return a 400 Bad Request response.
-	if err := context.ShouldBindJSON(&receipt); err != nil {
-		log.Println("Failed to bind receipt JSON:", err)
-		context.JSON(http.StatusBadRequest, gin.H{"error": "The receipt is invalid."})
-		return
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
 	}
 
-	// Generate a unique identifier for the receipt.
-	id := uuid.New().String()
-	log.Println("Generated receipt ID:", id)
+	ginWriter := configureLogging(cfg.Logging)
 
-	// Lock receiptStore and map the ID to a receipt.
-	storeLock.Lock()
-	receiptStore[id] = receipt
-	storeLock.Unlock()
-	log.Println("Receipt stored successfully.")
-
-	// Return the generated receipt ID in the response.
-	context.JSON(http.StatusOK, ReceiptResponse{ID: id})
-}
+	repo, err := newReceiptRepositoryFromEnv()
+	if err != nil {
+		log.Fatal("Failed to initialize receipt repository:", err)
+	}
 
-// getPoints handles the retrieval of points awarded for a given receipt.
-// It retrieves the receipt using its unique ID, calculates the points, and returns the result.
-func getPoints(context *gin.Context) {
-	// Retrieve the receipt ID from the request URL parameters.
-	id := context.Param("id")
-	log.Println("Fetching points for receipt ID:", id)
-
-	// Lock receiptStore and find the receipt by ID.
-	storeLock.Lock()
-	receipt, exists := receiptStore[id]
-	storeLock.Unlock()
-
-	// If the receipt does not exist, return a 404 Not Found response.
-	if !exists {
-		log.Println("No receipt found for ID:", id)
-		context.JSON(http.StatusNotFound, gin.H{"error": "No receipt found for that ID"})
-		return
+	rules := DefaultRuleSet()
+	if *rulesPath != "" {
+		rules, err = LoadRuleSet(*rulesPath)
+		if err != nil {
+			log.Fatal("Failed to load rules file:", err)
+		}
 	}
 
-	// Calculate the points awarded for the receipt.
-	points := calculatePoints(receipt)
-	log.Println("Points calculated for receipt ID:", id, "Total Points:", points)
+	handler := newReceiptHandler(repo, rules)
 
-	// Return the calculated points in the response.
-	context.JSON(http.StatusOK, PointsResponse{Points: points})
-}
+	gin.DefaultWriter = ginWriter
+	gin.DefaultErrorWriter = ginWriter
+	router := gin.Default()
 
-func calculatePoints(receipt Receipt) int {
-	points := 0
-
-	// One point for every alphanumeric character in the retailer name.
-
-	// Compile regex for alphanumeric characters
-	regex := regexp.MustCompile(`[a-zA-Z0-9]`)
-	// Find all matches in the retailer name
-	matches := regex.FindAllString(receipt.Retailer, -1)
-	// Count the number of matches
-	matchCount := len(matches)
-	// Add to points
-	points += matchCount
-	log.Println("Added", matchCount, "points for retailer:", receipt.Retailer)
-
-	// 50 points if the total is a round dollar amount with no cents.
-	if strings.HasSuffix(receipt.Total, ".00") {
-		points += 50
-		log.Println("Added 50 points for round dollar amount:", receipt.Total)
-	}
+	// Endpoint to submit a receipt for processing.
+	router.POST("/receipts/process", handler.processReceipt)
 
-	// 25 points if the total is a multiple of 0.25.
-	totalInCents, _ := strconv.Atoi(strings.ReplaceAll(receipt.Total, ".", ""))
-	if totalInCents % 25 == 0 {
-		points += 25
-		log.Println("Added 25 points for total being multiple of 0.25:", receipt.Total)
+	// Endpoint to retrieve the points awarded for a receipt.
+	router.GET("/receipts/:id/points", handler.getPoints)
+
+	// Endpoints to refund or adjust a previously processed receipt, and
+	// to inspect the ledger of events behind its current points total.
+	router.POST("/receipts/:id/refund", handler.refundReceipt)
+	router.POST("/receipts/:id/adjust", handler.adjustReceipt)
+	router.GET("/receipts/:id/ledger", handler.getLedger)
+
+	readTimeout, writeTimeout, idleTimeout, shutdownGrace := cfg.ServerTimeouts()
+	srv := &http.Server{
+		Addr:         cfg.Server.ListenAddr,
+		Handler:      router,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		IdleTimeout:  idleTimeout,
 	}
 
-	// 5 points for every two items on the receipt.
-	itemPoints := (len(receipt.Items) / 2) * 5
-	points += itemPoints
-	log.Println("Added", itemPoints, "points for item count.")
-
-	// If the trimmed length of the item description is a multiple of 3,
-	// multiply the price by 0.2 and round up to the nearest integer.
-	// The result is the number of points earned.
-	for _, item := range receipt.Items {
-		trimmedLen := len(strings.TrimSpace(item.ShortDescription))
-		if trimmedLen%3 == 0 {
-			price, _ := strconv.ParseFloat(item.Price, 64)
-			roundedPoints := int(math.Ceil(price * 0.2))
-			points += roundedPoints
-			log.Println("Added", roundedPoints, "points for item:", strings.TrimSpace(item.ShortDescription))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Println("Listening on", cfg.Server.ListenAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+			return
+		}
+		serverErr <- nil
+	}()
+
+	select {
+	case <-ctx.Done():
+		log.Println("Shutdown signal received, shutting down gracefully...")
+	case err := <-serverErr:
+		if err != nil {
+			log.Fatal("Server failed:", err)
 		}
+		return
 	}
 
-	// 6 points if the day in the purchase date is odd.
-	dateParts := strings.Split(receipt.PurchaseDate, "-")
-	if day, err := strconv.Atoi(dateParts[2]); err == nil && day%2 == 1 {
-		points += 6
-		log.Println("Added 6 points for odd purchase date:", receipt.PurchaseDate)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Fatal("Graceful shutdown failed:", err)
 	}
+	log.Println("Server shut down cleanly.")
+}
 
-	// 10 points if the time of purchase is after 2:00pm and before 4:00pm.
-	if purchaseTime, err := time.Parse("15:04", receipt.PurchaseTime); err == nil {
-		hour, min := purchaseTime.Hour(), purchaseTime.Minute()
-		if (hour == 14 && min > 0) || (hour == 15) {
-			points += 10
-			log.Println("Added 10 points for purchase time:", receipt.PurchaseTime)
+// canonicalReceiptHash computes a stable SHA-256 hash of a receipt's
+// contents, used to recognize semantically identical resubmissions.
+// The retailer name is whitespace-normalized and items are sorted by
+// (shortDescription, price) before hashing so that reordered items or
+// incidental whitespace differences still produce the same hash.
+func canonicalReceiptHash(receipt Receipt) string {
+	items := make([]Item, len(receipt.Items))
+	copy(items, receipt.Items)
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].ShortDescription != items[j].ShortDescription {
+			return items[i].ShortDescription < items[j].ShortDescription
 		}
+		return items[i].Price < items[j].Price
+	})
+
+	var builder strings.Builder
+	builder.WriteString(strings.Join(strings.Fields(receipt.Retailer), " "))
+	builder.WriteString("|")
+	builder.WriteString(receipt.PurchaseDate)
+	builder.WriteString("|")
+	builder.WriteString(receipt.PurchaseTime)
+	builder.WriteString("|")
+	builder.WriteString(receipt.Total)
+	for _, item := range items {
+		builder.WriteString("|")
+		builder.WriteString(strings.Join(strings.Fields(item.ShortDescription), " "))
+		builder.WriteString(":")
+		builder.WriteString(item.Price)
 	}
-	
-	log.Println("Final calculated points:", points)
-	return points
+
+	normalized := strings.ToLower(builder.String())
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
 }
+
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter builds a fresh Gin router wired to a receiptHandler
+// backed by an in-memory repository, and resets the package-level
+// idempotency caches so tests don't bleed into each other.
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	hashToID = make(map[string]string)
+	idempotencyKeys = make(map[string]idempotencyRecord)
+
+	handler := newReceiptHandler(newMemoryReceiptRepository(), DefaultRuleSet())
+
+	router := gin.New()
+	router.POST("/receipts/process", handler.processReceipt)
+	router.GET("/receipts/:id/points", handler.getPoints)
+	return router
+}
+
+func postReceipt(router *gin.Engine, body string, idempotencyKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/receipts/process", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func decodeReceiptResponse(t *testing.T, recorder *httptest.ResponseRecorder) ReceiptResponse {
+	t.Helper()
+	var resp ReceiptResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response body %q: %v", recorder.Body.String(), err)
+	}
+	return resp
+}
+
+const sampleReceipt = `{
+	"retailer": "Target",
+	"purchaseDate": "2022-01-01",
+	"purchaseTime": "13:01",
+	"items": [
+		{"shortDescription": "Mountain Dew 12PK", "price": "6.49"},
+		{"shortDescription": "Emils Cheese Pizza", "price": "12.25"}
+	],
+	"total": "18.74"
+}`
+
+// sampleReceiptReordered is semantically identical to sampleReceipt but
+// with its items swapped and extra whitespace in the retailer name.
+const sampleReceiptReordered = `{
+	"retailer": "  Target  ",
+	"purchaseDate": "2022-01-01",
+	"purchaseTime": "13:01",
+	"items": [
+		{"shortDescription": "Emils Cheese Pizza", "price": "12.25"},
+		{"shortDescription": "Mountain Dew 12PK", "price": "6.49"}
+	],
+	"total": "18.74"
+}`
+
+const otherReceipt = `{
+	"retailer": "Walgreens",
+	"purchaseDate": "2022-01-02",
+	"purchaseTime": "08:13",
+	"items": [
+		{"shortDescription": "Pepsi - 12-oz", "price": "1.25"}
+	],
+	"total": "1.25"
+}`
+
+func TestProcessReceipt_IdenticalResubmitIsIdempotent(t *testing.T) {
+	router := newTestRouter()
+
+	first := postReceipt(router, sampleReceipt, "")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected 200 on first submission, got %d", first.Code)
+	}
+	firstID := decodeReceiptResponse(t, first).ID
+
+	second := postReceipt(router, sampleReceipt, "")
+	if second.Code != http.StatusOK {
+		t.Fatalf("expected 200 on resubmission, got %d", second.Code)
+	}
+	secondID := decodeReceiptResponse(t, second).ID
+
+	if firstID != secondID {
+		t.Fatalf("expected resubmission to reuse ID %q, got %q", firstID, secondID)
+	}
+}
+
+func TestProcessReceipt_ReorderedItemsAreIdempotent(t *testing.T) {
+	router := newTestRouter()
+
+	first := postReceipt(router, sampleReceipt, "")
+	firstID := decodeReceiptResponse(t, first).ID
+
+	second := postReceipt(router, sampleReceiptReordered, "")
+	if second.Code != http.StatusOK {
+		t.Fatalf("expected 200 for reordered-but-equal receipt, got %d", second.Code)
+	}
+	secondID := decodeReceiptResponse(t, second).ID
+
+	if firstID != secondID {
+		t.Fatalf("expected reordered receipt to reuse ID %q, got %q", firstID, secondID)
+	}
+}
+
+func TestProcessReceipt_IdempotencyKeyReusedWithSameBody(t *testing.T) {
+	router := newTestRouter()
+
+	first := postReceipt(router, sampleReceipt, "retry-key-1")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected 200 on first submission, got %d", first.Code)
+	}
+	firstID := decodeReceiptResponse(t, first).ID
+
+	second := postReceipt(router, sampleReceipt, "retry-key-1")
+	if second.Code != http.StatusOK {
+		t.Fatalf("expected 200 on retried submission, got %d", second.Code)
+	}
+	secondID := decodeReceiptResponse(t, second).ID
+
+	if firstID != secondID {
+		t.Fatalf("expected retried submission to reuse ID %q, got %q", firstID, secondID)
+	}
+}
+
+func TestGetPoints_ExplainQueryParam(t *testing.T) {
+	router := newTestRouter()
+	id := decodeReceiptResponse(t, postReceipt(router, sampleReceipt, "")).ID
+
+	getPoints := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/receipts/"+id+"/points"+query, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	plain := getPoints("")
+	if plain.Code != http.StatusOK {
+		t.Fatalf("expected 200 with no explain param, got %d", plain.Code)
+	}
+	var plainResp PointsResponse
+	if err := json.Unmarshal(plain.Body.Bytes(), &plainResp); err != nil {
+		t.Fatalf("failed to decode plain points response: %v", err)
+	}
+
+	zero := getPoints("?explain=0")
+	if zero.Code != http.StatusOK {
+		t.Fatalf("expected 200 with explain=0, got %d", zero.Code)
+	}
+	var zeroResp PointsResponse
+	if err := json.Unmarshal(zero.Body.Bytes(), &zeroResp); err != nil {
+		t.Fatalf("explain=0 should return the plain PointsResponse shape, got %q: %v", zero.Body.String(), err)
+	}
+	if zeroResp.Points != plainResp.Points {
+		t.Errorf("explain=0 Points = %d, want %d", zeroResp.Points, plainResp.Points)
+	}
+
+	one := getPoints("?explain=1")
+	if one.Code != http.StatusOK {
+		t.Fatalf("expected 200 with explain=1, got %d", one.Code)
+	}
+	var explainedResp ExplainedPointsResponse
+	if err := json.Unmarshal(one.Body.Bytes(), &explainedResp); err != nil {
+		t.Fatalf("failed to decode explained points response: %v", err)
+	}
+	if len(explainedResp.Breakdown) == 0 {
+		t.Error("expected explain=1 to return a non-empty rule breakdown")
+	}
+}
+
+func TestProcessReceipt_IdempotencyKeyReusedWithDifferentBodyConflicts(t *testing.T) {
+	router := newTestRouter()
+
+	first := postReceipt(router, sampleReceipt, "retry-key-2")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected 200 on first submission, got %d", first.Code)
+	}
+
+	second := postReceipt(router, otherReceipt, "retry-key-2")
+	if second.Code != http.StatusConflict {
+		t.Fatalf("expected 409 when reusing Idempotency-Key with a different body, got %d", second.Code)
+	}
+}
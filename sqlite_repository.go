@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteReceiptRepository is a ReceiptRepository backed by SQLite. It
+// gives a single node durability across restarts without requiring a
+// separate database process.
+type sqliteReceiptRepository struct {
+	db *sql.DB
+}
+
+// newSQLiteReceiptRepository opens (and, if necessary, creates) the
+// SQLite database at dataSourceName, applies the embedded schema
+// migrations in migrations/0001_init.sql and
+// migrations/sqlite/0002_ledger.sql (see schema.go), and returns a
+// ReceiptRepository backed by it. Applying the migrations here means a
+// fresh file (or an in-memory database, as the tests use) is always
+// usable without a separate migration step.
+func newSQLiteReceiptRepository(dataSourceName string) (*sqliteReceiptRepository, error) {
+	db, err := sql.Open("sqlite3", dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("opening sqlite database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("connecting to sqlite database: %w", err)
+	}
+	// SQLite only supports a single writer at a time; serialize writes
+	// through one connection to avoid "database is locked" errors.
+	db.SetMaxOpenConns(1)
+	if err := applySQLiteMigrations(context.Background(), db); err != nil {
+		return nil, fmt.Errorf("applying sqlite schema: %w", err)
+	}
+	return &sqliteReceiptRepository{db: db}, nil
+}
+
+func (r *sqliteReceiptRepository) Save(ctx context.Context, id string, receipt Receipt) error {
+	itemsJSON, err := json.Marshal(receipt.Items)
+	if err != nil {
+		return fmt.Errorf("marshaling receipt items: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO receipts (id, retailer, purchase_date, purchase_time, total, items_json)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			retailer = excluded.retailer,
+			purchase_date = excluded.purchase_date,
+			purchase_time = excluded.purchase_time,
+			total = excluded.total,
+			items_json = excluded.items_json
+	`, id, receipt.Retailer, receipt.PurchaseDate, receipt.PurchaseTime, receipt.Total, string(itemsJSON))
+	if err != nil {
+		return fmt.Errorf("saving receipt %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *sqliteReceiptRepository) Get(ctx context.Context, id string) (Receipt, bool, error) {
+	var receipt Receipt
+	var itemsJSON string
+	row := r.db.QueryRowContext(ctx, `
+		SELECT retailer, purchase_date, purchase_time, total, items_json
+		FROM receipts WHERE id = ?
+	`, id)
+	if err := row.Scan(&receipt.Retailer, &receipt.PurchaseDate, &receipt.PurchaseTime, &receipt.Total, &itemsJSON); err != nil {
+		if err == sql.ErrNoRows {
+			return Receipt{}, false, nil
+		}
+		return Receipt{}, false, fmt.Errorf("fetching receipt %s: %w", id, err)
+	}
+	if err := json.Unmarshal([]byte(itemsJSON), &receipt.Items); err != nil {
+		return Receipt{}, false, fmt.Errorf("unmarshaling receipt items for %s: %w", id, err)
+	}
+	return receipt, true, nil
+}
+
+func (r *sqliteReceiptRepository) AppendLedgerEntry(ctx context.Context, id string, entry LedgerEntry) error {
+	// seq is assigned by SQLite itself (see migrations/sqlite/0002_ledger.sql),
+	// so ordering doesn't depend on reading and re-writing a MAX() here.
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO ledger_entries (id, occurred_at, kind, delta, reason)
+		VALUES (?, ?, ?, ?, ?)
+	`, id, entry.Timestamp.UTC().Format(time.RFC3339Nano), entry.Kind, entry.Delta, entry.Reason)
+	if err != nil {
+		return fmt.Errorf("appending ledger entry for %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *sqliteReceiptRepository) GetLedger(ctx context.Context, id string) ([]LedgerEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT occurred_at, kind, delta, reason FROM ledger_entries
+		WHERE id = ? ORDER BY seq ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("fetching ledger for %s: %w", id, err)
+	}
+	defer rows.Close()
+
+	entries := []LedgerEntry{}
+	for rows.Next() {
+		var entry LedgerEntry
+		var occurredAt string
+		if err := rows.Scan(&occurredAt, &entry.Kind, &entry.Delta, &entry.Reason); err != nil {
+			return nil, fmt.Errorf("scanning ledger entry for %s: %w", id, err)
+		}
+		entry.Timestamp, err = time.Parse(time.RFC3339Nano, occurredAt)
+		if err != nil {
+			return nil, fmt.Errorf("parsing ledger entry timestamp for %s: %w", id, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading ledger for %s: %w", id, err)
+	}
+	return entries, nil
+}